@@ -21,6 +21,7 @@ import (
 	"errors"
 	"net"
 	"sync"
+	"time"
 )
 
 // NewSingleUseDialer returns a "single use" dialer. The first dial will
@@ -32,6 +33,12 @@ func NewSingleUseDialer(conn net.Conn) *SingleUseDialer {
 
 // SingleUseDialer is the Dialer returned by [NewSingleUseDialer].
 type SingleUseDialer struct {
+	// DialTimeout, if nonzero, simulates a dial that takes this long to
+	// complete, so that a canceled or expired ctx can interrupt it. It is
+	// useful in tests that exercise context cancellation without an actual
+	// network dial taking place.
+	DialTimeout time.Duration
+
 	mu   sync.Mutex
 	conn net.Conn
 }
@@ -44,13 +51,41 @@ var ErrNoConnReuse = errors.New("cannot reuse connection")
 //
 // This method signature is compatible with the [net/http] package.
 //
-// All arguments are ignored and we return the connection (once) or [ErrNoConnRuse].
+// All arguments but ctx are ignored and we return the connection (once) or
+// [ErrNoConnReuse]. If ctx is already canceled or its deadline has passed,
+// we return [ctx.Err] without consuming the configured connection. If
+// [*SingleUseDialer.DialTimeout] is set, we simulate a dial taking that long,
+// which ctx can still interrupt. If ctx carries a deadline, we apply it to
+// the returned connection via [net.Conn.SetDeadline] for the dial's duration,
+// restoring it to none on handoff so the caller starts from a clean slate.
 func (sud *SingleUseDialer) DialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	sud.mu.Lock()
 	defer sud.mu.Unlock()
 	if sud.conn == nil {
 		return nil, ErrNoConnReuse
 	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := sud.conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+		defer sud.conn.SetDeadline(time.Time{})
+	}
+
+	if sud.DialTimeout > 0 {
+		timer := time.NewTimer(sud.DialTimeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
 	var conn net.Conn
 	conn, sud.conn = sud.conn, nil
 	return conn, nil
@@ -65,3 +100,236 @@ func (d *SingleUseDialer) DialTLSContext(
 	ctx context.Context, network, address string, cfg *tls.Config) (net.Conn, error) {
 	return d.DialContext(ctx, network, address)
 }
+
+// NewPooledDialer returns a dialer that hands out pre-established connections
+// keyed by network and address. Unlike [NewSingleUseDialer], which ignores its
+// arguments, a [*PooledDialer] routes each dial to the queue of connections
+// registered for the matching network+address and returns the first
+// not-yet-consumed one from that queue, in order. This allows staging several
+// "happy eyeballs"-style candidate connections for the same target, and lets
+// a single dialer serve several targets (e.g., one TCP and one TLS connection
+// to different origins) for injection into a single [http.Transport].
+//
+// When a key's queue is exhausted (or was never registered), dialing that
+// key returns [ErrNoConnReuse].
+func NewPooledDialer(conns map[string][]net.Conn) *PooledDialer {
+	pool := make(map[string][]net.Conn, len(conns))
+	for key, queue := range conns {
+		pool[key] = append([]net.Conn{}, queue...)
+	}
+	return &PooledDialer{conns: pool}
+}
+
+// PooledDialer is the Dialer returned by [NewPooledDialer].
+type PooledDialer struct {
+	mu    sync.Mutex
+	conns map[string][]net.Conn
+}
+
+// poolKey builds the map key [*PooledDialer] uses to route dials.
+func poolKey(network, address string) string {
+	return network + " " + address
+}
+
+// DialContext returns the next not-yet-consumed connection registered for
+// network+address, or [ErrNoConnReuse] if none remain.
+//
+// This method signature is compatible with the [net/http] package.
+func (d *PooledDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key := poolKey(network, address)
+	queue := d.conns[key]
+	if len(queue) == 0 {
+		return nil, ErrNoConnReuse
+	}
+	var conn net.Conn
+	conn, d.conns[key] = queue[0], queue[1:]
+	return conn, nil
+}
+
+// DialTLSContext returns the next not-yet-consumed connection registered for
+// network+address, or [ErrNoConnReuse] if none remain.
+//
+// This method signature is compatible with the [golang.org/x/net/http2] package.
+func (d *PooledDialer) DialTLSContext(
+	ctx context.Context, network, address string, cfg *tls.Config) (net.Conn, error) {
+	return d.DialContext(ctx, network, address)
+}
+
+// ErrAllConnsFailed is the error [*FallbackDialer.DialContext] returns once
+// every connection it was given has failed.
+var ErrAllConnsFailed = errors.New("all connections failed")
+
+// NewFallbackDialer returns a dialer that tries the given connections one at
+// a time, in order. The first [FallbackDialer.DialContext] call returns the
+// first connection; like [*SingleUseDialer], a further call while that
+// connection is still in use fails with [ErrNoConnReuse]. What makes this
+// dialer different is how it reacts to failure: if the caller invokes
+// [*FallbackDialer.ReportFailure] with the returned connection, or closes it
+// before any successful read, the dialer treats that connection as failed and
+// the next [FallbackDialer.DialContext] call returns the next connection in
+// the list instead of [ErrNoConnReuse]. Once every connection has failed,
+// dialing returns [ErrAllConnsFailed].
+//
+// This mirrors dialing a pre-resolved set of addresses: a caller who has
+// pre-connected to several candidate IPs can inject all of them and let the
+// HTTP transport transparently fall back to the next one by re-dialing.
+func NewFallbackDialer(conns ...net.Conn) *FallbackDialer {
+	return &FallbackDialer{conns: append([]net.Conn{}, conns...)}
+}
+
+// FallbackDialer is the Dialer returned by [NewFallbackDialer].
+type FallbackDialer struct {
+	mu      sync.Mutex
+	conns   []net.Conn
+	index   int
+	current net.Conn
+	lastErr error
+}
+
+// DialContext returns the current connection in the list, or advances to and
+// returns the next one if the previous connection was reported failed (see
+// [*FallbackDialer.ReportFailure]). It fails with [ErrNoConnReuse] if the
+// current connection is still in use, or with [ErrAllConnsFailed] once every
+// connection in the list has failed.
+//
+// This method signature is compatible with the [net/http] package.
+func (d *FallbackDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.current != nil {
+		return nil, ErrNoConnReuse
+	}
+	if d.index >= len(d.conns) {
+		if d.lastErr != nil {
+			return nil, d.lastErr
+		}
+		return nil, ErrNoConnReuse
+	}
+	conn := &fallbackConn{Conn: d.conns[d.index], dialer: d}
+	d.current = conn
+	return conn, nil
+}
+
+// DialTLSContext behaves like [*FallbackDialer.DialContext].
+//
+// This method signature is compatible with the [golang.org/x/net/http2] package.
+func (d *FallbackDialer) DialTLSContext(
+	ctx context.Context, network, address string, cfg *tls.Config) (net.Conn, error) {
+	return d.DialContext(ctx, network, address)
+}
+
+// ReportFailure marks conn, which must be the connection most recently
+// returned by [*FallbackDialer.DialContext], as failed so that the next
+// dial advances to the following connection in the list. It is a no-op if
+// conn is not the current connection (e.g., it was already reported, or it
+// was never returned by this dialer).
+func (d *FallbackDialer) ReportFailure(conn net.Conn) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if conn != d.current {
+		return
+	}
+	d.lastErr = ErrAllConnsFailed
+	d.index++
+	d.current = nil
+}
+
+// fallbackConn wraps a connection handed out by [*FallbackDialer] so that a
+// [*FallbackDialer.ReportFailure] call is implied by closing the connection
+// before any successful read from it, matching the behavior of a caller that
+// gives up on a connection without explicitly reporting why.
+type fallbackConn struct {
+	net.Conn
+	dialer *FallbackDialer
+
+	mu     sync.Mutex
+	readOK bool
+}
+
+// Read delegates to the wrapped connection and remembers whether it ever
+// completed a successful read.
+func (c *fallbackConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if err == nil {
+		c.mu.Lock()
+		c.readOK = true
+		c.mu.Unlock()
+	}
+	return n, err
+}
+
+// Close reports this connection as failed to the owning dialer, unless a
+// read already succeeded on it, and then closes the wrapped connection.
+func (c *fallbackConn) Close() error {
+	c.mu.Lock()
+	readOK := c.readOK
+	c.mu.Unlock()
+	if !readOK {
+		c.dialer.ReportFailure(c)
+	}
+	return c.Conn.Close()
+}
+
+// NewSingleUseListener returns a "single use" [net.Listener], the dual of
+// [NewSingleUseDialer]: the first [net.Listener.Accept] call returns conn;
+// subsequent calls block until the listener is closed, at which point they
+// return [ErrNoConnReuse]. It is useful for feeding a pre-established
+// connection into an API that expects to run its own accept loop, such as
+// [http.Server.Serve].
+func NewSingleUseListener(conn net.Conn) net.Listener {
+	return &SingleUseListener{
+		conn: conn,
+		addr: conn.LocalAddr(),
+		done: make(chan struct{}),
+	}
+}
+
+// SingleUseListener is the [net.Listener] returned by [NewSingleUseListener].
+type SingleUseListener struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	addr   net.Addr
+	done   chan struct{}
+	closed bool
+}
+
+// Accept returns the injected connection on the first call, unless the
+// listener has already been closed. Subsequent calls block until
+// [*SingleUseListener.Close] is invoked, at which point they return
+// [ErrNoConnReuse].
+func (l *SingleUseListener) Accept() (net.Conn, error) {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil, ErrNoConnReuse
+	}
+	if l.conn != nil {
+		conn := l.conn
+		l.conn = nil
+		l.mu.Unlock()
+		return conn, nil
+	}
+	l.mu.Unlock()
+
+	<-l.done
+	return nil, ErrNoConnReuse
+}
+
+// Close closes the listener, unblocking any pending or future
+// [*SingleUseListener.Accept] call. It is safe to call more than once.
+func (l *SingleUseListener) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.closed {
+		l.closed = true
+		close(l.done)
+	}
+	return nil
+}
+
+// Addr returns the injected connection's [net.Conn.LocalAddr].
+func (l *SingleUseListener) Addr() net.Addr {
+	return l.addr
+}
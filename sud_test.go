@@ -9,6 +9,7 @@ import (
 	"net"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -113,6 +114,372 @@ func TestErrNoConnReuse(t *testing.T) {
 	})
 }
 
+func TestNewPooledDialer(t *testing.T) {
+	connA := &net.TCPConn{}
+	dialer := NewPooledDialer(map[string][]net.Conn{
+		"tcp example.com:443": {connA},
+	})
+	require.NotNil(t, dialer)
+	assert.Equal(t, connA, dialer.conns["tcp example.com:443"][0])
+}
+
+func TestPooledDialer_DialContext(t *testing.T) {
+	t.Run("routes by network and address", func(t *testing.T) {
+		// Arrange: two distinct targets, each with its own connection
+		tcpConn := &net.TCPConn{}
+		udpConn := &net.UDPConn{}
+		dialer := NewPooledDialer(map[string][]net.Conn{
+			"tcp example.com:443": {tcpConn},
+			"udp other.com:53":    {udpConn},
+		})
+
+		// Act
+		got1, err1 := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+		got2, err2 := dialer.DialContext(context.Background(), "udp", "other.com:53")
+
+		// Assert: each dial returns the connection registered for its own key
+		require.NoError(t, err1)
+		assert.Equal(t, tcpConn, got1)
+		require.NoError(t, err2)
+		assert.Equal(t, udpConn, got2)
+	})
+
+	t.Run("returns candidates in order for a key", func(t *testing.T) {
+		// Arrange: stage multiple "happy eyeballs" candidates for one target
+		first := &net.TCPConn{}
+		second := &net.TCPConn{}
+		dialer := NewPooledDialer(map[string][]net.Conn{
+			"tcp example.com:443": {first, second},
+		})
+
+		// Act
+		got1, err1 := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+		got2, err2 := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+
+		// Assert: candidates are handed out in registration order
+		require.NoError(t, err1)
+		assert.Equal(t, first, got1)
+		require.NoError(t, err2)
+		assert.Equal(t, second, got2)
+	})
+
+	t.Run("exhausted key fails with ErrNoConnReuse", func(t *testing.T) {
+		// Arrange: a key with a single queued connection, consumed once
+		conn := &net.TCPConn{}
+		dialer := NewPooledDialer(map[string][]net.Conn{
+			"tcp example.com:443": {conn},
+		})
+		_, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+		require.NoError(t, err)
+
+		// Act: dial the same key again
+		got, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+
+		// Assert
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, ErrNoConnReuse)
+	})
+
+	t.Run("unregistered key fails with ErrNoConnReuse", func(t *testing.T) {
+		// Arrange: a dialer with no entry for the dialed key
+		dialer := NewPooledDialer(map[string][]net.Conn{
+			"tcp example.com:443": {&net.TCPConn{}},
+		})
+
+		// Act
+		got, err := dialer.DialContext(context.Background(), "tcp", "unregistered.com:443")
+
+		// Assert
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, ErrNoConnReuse)
+	})
+}
+
+func TestPooledDialer_DialTLSContext(t *testing.T) {
+	t.Run("delegates to DialContext", func(t *testing.T) {
+		// Arrange: create a dialer with a connection registered for the target
+		conn := &net.TCPConn{}
+		dialer := NewPooledDialer(map[string][]net.Conn{
+			"tcp example.com:443": {conn},
+		})
+
+		// Act: dial with DialTLSContext
+		got, err := dialer.DialTLSContext(context.Background(), "tcp", "example.com:443", &tls.Config{})
+
+		// Assert: should return the registered connection
+		require.NoError(t, err)
+		assert.Equal(t, conn, got)
+	})
+}
+
+func TestPooledDialer_ConcurrentAccess(t *testing.T) {
+	// Arrange: a pool with as many queued connections as goroutines
+	const numGoroutines = 100
+	queue := make([]net.Conn, numGoroutines)
+	for i := range queue {
+		queue[i] = &net.TCPConn{}
+	}
+	dialer := NewPooledDialer(map[string][]net.Conn{
+		"tcp example.com:443": queue,
+	})
+
+	// Act: dial concurrently from multiple goroutines
+	var wg sync.WaitGroup
+	wg.Add(numGoroutines)
+
+	results := make(chan net.Conn, numGoroutines)
+	for range numGoroutines {
+		go func() {
+			defer wg.Done()
+			got, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+			require.NoError(t, err)
+			results <- got
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	// Assert: every queued connection was handed out exactly once
+	seen := make(map[net.Conn]bool, numGoroutines)
+	for got := range results {
+		assert.False(t, seen[got])
+		seen[got] = true
+	}
+	assert.Len(t, seen, numGoroutines)
+}
+
+func TestNewFallbackDialer(t *testing.T) {
+	c1, c1peer := net.Pipe()
+	defer c1peer.Close()
+	dialer := NewFallbackDialer(c1)
+	require.NotNil(t, dialer)
+	assert.Len(t, dialer.conns, 1)
+}
+
+func TestFallbackDialer_DialContext(t *testing.T) {
+	t.Run("second dial while the current connection is active fails", func(t *testing.T) {
+		// Arrange
+		c1, c1peer := net.Pipe()
+		defer c1peer.Close()
+		dialer := NewFallbackDialer(c1)
+		_, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+		require.NoError(t, err)
+
+		// Act: dial again without reporting a failure
+		got, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+
+		// Assert
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, ErrNoConnReuse)
+	})
+
+	t.Run("closing before any read advances to the next connection", func(t *testing.T) {
+		// Arrange
+		c1, c1peer := net.Pipe()
+		defer c1peer.Close()
+		c2, c2peer := net.Pipe()
+		defer c2peer.Close()
+		dialer := NewFallbackDialer(c1, c2)
+
+		// Act: close the first connection before reading from it
+		got1, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+		require.NoError(t, err)
+		require.NoError(t, got1.Close())
+
+		got2, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+
+		// Assert: the second dial returns the second connection
+		require.NoError(t, err)
+		assert.Same(t, c2, got2.(*fallbackConn).Conn)
+	})
+
+	t.Run("a successful read protects the connection from close-as-failure", func(t *testing.T) {
+		// Arrange
+		c1, c1peer := net.Pipe()
+		dialer := NewFallbackDialer(c1)
+		got, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+		require.NoError(t, err)
+
+		go func() {
+			_, _ = c1peer.Write([]byte("hi"))
+		}()
+		buf := make([]byte, 2)
+		_, err = got.Read(buf)
+		require.NoError(t, err)
+
+		// Act: close after a successful read
+		require.NoError(t, got.Close())
+		_ = c1peer.Close()
+		got2, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+
+		// Assert: the connection was not marked failed, so it is still the
+		// current connection and dialing again fails with ErrNoConnReuse
+		assert.Nil(t, got2)
+		assert.ErrorIs(t, err, ErrNoConnReuse)
+	})
+
+	t.Run("returns ErrAllConnsFailed once every connection has failed", func(t *testing.T) {
+		// Arrange
+		c1, c1peer := net.Pipe()
+		defer c1peer.Close()
+		dialer := NewFallbackDialer(c1)
+		got, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+		require.NoError(t, err)
+		require.NoError(t, got.Close())
+
+		// Act: dial again with no more connections left
+		got2, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+
+		// Assert
+		assert.Nil(t, got2)
+		assert.ErrorIs(t, err, ErrAllConnsFailed)
+	})
+}
+
+func TestFallbackDialer_ReportFailure(t *testing.T) {
+	t.Run("advances to the next connection", func(t *testing.T) {
+		// Arrange
+		c1, c1peer := net.Pipe()
+		defer c1peer.Close()
+		c2, c2peer := net.Pipe()
+		defer c2peer.Close()
+		dialer := NewFallbackDialer(c1, c2)
+		got1, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+		require.NoError(t, err)
+
+		// Act
+		dialer.ReportFailure(got1)
+		got2, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Same(t, c2, got2.(*fallbackConn).Conn)
+	})
+
+	t.Run("is a no-op for a stale connection", func(t *testing.T) {
+		// Arrange
+		c1, c1peer := net.Pipe()
+		defer c1peer.Close()
+		dialer := NewFallbackDialer(c1)
+		got1, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+		require.NoError(t, err)
+
+		// Act: report failure for a connection this dialer never returned
+		dialer.ReportFailure(&net.TCPConn{})
+
+		// Assert: the current connection is unaffected
+		got2, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+		assert.Nil(t, got2)
+		assert.ErrorIs(t, err, ErrNoConnReuse)
+		_ = got1
+	})
+}
+
+func TestSingleUseDialer_DialContext_ContextHandling(t *testing.T) {
+	t.Run("already canceled context", func(t *testing.T) {
+		// Arrange
+		conn := &net.TCPConn{}
+		dialer := NewSingleUseDialer(conn)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Act
+		got, err := dialer.DialContext(ctx, "tcp", "example.com:443")
+
+		// Assert: fails without consuming the configured connection
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, context.Canceled)
+		got, err = dialer.DialContext(context.Background(), "tcp", "example.com:443")
+		require.NoError(t, err)
+		assert.Equal(t, conn, got)
+	})
+
+	t.Run("already expired deadline", func(t *testing.T) {
+		// Arrange
+		conn := &net.TCPConn{}
+		dialer := NewSingleUseDialer(conn)
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Minute))
+		defer cancel()
+
+		// Act
+		got, err := dialer.DialContext(ctx, "tcp", "example.com:443")
+
+		// Assert: fails without consuming the configured connection
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		got, err = dialer.DialContext(context.Background(), "tcp", "example.com:443")
+		require.NoError(t, err)
+		assert.Equal(t, conn, got)
+	})
+
+	t.Run("active context without deadline", func(t *testing.T) {
+		// Arrange
+		conn := &net.TCPConn{}
+		dialer := NewSingleUseDialer(conn)
+
+		// Act
+		got, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, conn, got)
+	})
+
+	t.Run("active context with future deadline applies and restores it", func(t *testing.T) {
+		// Arrange: a real conn so SetDeadline actually takes effect
+		server, client := net.Pipe()
+		defer server.Close()
+		dialer := NewSingleUseDialer(client)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		// Act
+		got, err := dialer.DialContext(ctx, "tcp", "example.com:443")
+
+		// Assert: dial succeeds and the deadline was restored to none on handoff,
+		// so a read made after the ctx deadline has passed still succeeds
+		require.NoError(t, err)
+		assert.Equal(t, client, got)
+		<-ctx.Done()
+		go func() {
+			_, _ = server.Write([]byte("x"))
+		}()
+		_, err = got.Read(make([]byte, 1))
+		assert.NoError(t, err)
+	})
+
+	t.Run("context canceled while simulating a dial", func(t *testing.T) {
+		// Arrange: a dial that takes longer than the context allows to cancel
+		conn := &net.TCPConn{}
+		dialer := &SingleUseDialer{DialTimeout: time.Hour}
+		dialer.conn = conn
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		// Act
+		got, err := dialer.DialContext(ctx, "tcp", "example.com:443")
+
+		// Assert: fails without consuming the configured connection
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, conn, dialer.conn)
+	})
+
+	t.Run("dial completes once the simulated timeout elapses", func(t *testing.T) {
+		// Arrange
+		conn := &net.TCPConn{}
+		dialer := &SingleUseDialer{DialTimeout: 10 * time.Millisecond}
+		dialer.conn = conn
+
+		// Act
+		got, err := dialer.DialContext(context.Background(), "tcp", "example.com:443")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, conn, got)
+	})
+}
+
 func TestSingleUseDialer_ConcurrentAccess(t *testing.T) {
 	// Arrange: create a dialer with a mock connection
 	conn := &net.TCPConn{}
@@ -157,3 +524,136 @@ func TestSingleUseDialer_ConcurrentAccess(t *testing.T) {
 	}
 	assert.Equal(t, numGoroutines-1, failureCount)
 }
+
+func TestNewSingleUseListener(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	listener := NewSingleUseListener(client)
+	require.NotNil(t, listener)
+}
+
+func TestSingleUseListener_Accept(t *testing.T) {
+	t.Run("first accept succeeds", func(t *testing.T) {
+		// Arrange
+		conn := &net.TCPConn{}
+		listener := NewSingleUseListener(conn)
+
+		// Act
+		got, err := listener.Accept()
+
+		// Assert: should succeed and return the injected connection
+		require.NoError(t, err)
+		assert.Equal(t, conn, got)
+	})
+
+	t.Run("second accept blocks until close then fails with ErrNoConnReuse", func(t *testing.T) {
+		// Arrange: consume the injected connection
+		conn := &net.TCPConn{}
+		listener := NewSingleUseListener(conn)
+		_, err := listener.Accept()
+		require.NoError(t, err)
+
+		// Act: a second accept should block
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := listener.Accept()
+			errCh <- err
+		}()
+
+		select {
+		case <-errCh:
+			t.Fatal("Accept returned before Close was called")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		// Assert: closing the listener unblocks it with ErrNoConnReuse
+		require.NoError(t, listener.Close())
+		assert.ErrorIs(t, <-errCh, ErrNoConnReuse)
+	})
+
+	t.Run("accept after close fails even if never called before", func(t *testing.T) {
+		// Arrange: close the listener before Accept is ever called
+		conn := &net.TCPConn{}
+		listener := NewSingleUseListener(conn)
+		require.NoError(t, listener.Close())
+
+		// Act
+		got, err := listener.Accept()
+
+		// Assert: the injected connection must not be handed out
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, ErrNoConnReuse)
+	})
+}
+
+func TestSingleUseListener_Addr(t *testing.T) {
+	// Arrange: a real conn so LocalAddr is meaningful
+	server, client := net.Pipe()
+	defer server.Close()
+	listener := NewSingleUseListener(client)
+
+	// Act
+	addr := listener.Addr()
+
+	// Assert
+	assert.Equal(t, client.LocalAddr(), addr)
+}
+
+func TestSingleUseListener_Close(t *testing.T) {
+	t.Run("is idempotent", func(t *testing.T) {
+		conn := &net.TCPConn{}
+		listener := NewSingleUseListener(conn)
+		require.NoError(t, listener.Close())
+		require.NoError(t, listener.Close())
+	})
+}
+
+func TestSingleUseListener_ConcurrentAccess(t *testing.T) {
+	// Arrange: create a listener with a mock connection
+	conn := &net.TCPConn{}
+	listener := NewSingleUseListener(conn)
+
+	// Act: attempt to accept concurrently from multiple goroutines
+	const numGoroutines = 100
+	var wg sync.WaitGroup
+	wg.Add(numGoroutines)
+
+	successes := make(chan net.Conn, numGoroutines)
+	failures := make(chan error, numGoroutines)
+
+	for range numGoroutines {
+		go func() {
+			defer wg.Done()
+			got, err := listener.Accept()
+			if err != nil {
+				failures <- err
+			} else {
+				successes <- got
+			}
+		}()
+	}
+
+	// Give the goroutines that missed the connection time to block on Accept,
+	// then close so they can all observe ErrNoConnReuse.
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, listener.Close())
+	wg.Wait()
+	close(successes)
+	close(failures)
+
+	// Assert: exactly one goroutine should succeed
+	var successCount int
+	for c := range successes {
+		assert.Equal(t, conn, c)
+		successCount++
+	}
+	assert.Equal(t, 1, successCount)
+
+	// Assert: all other goroutines should fail with ErrNoConnReuse
+	var failureCount int
+	for err := range failures {
+		assert.ErrorIs(t, err, ErrNoConnReuse)
+		failureCount++
+	}
+	assert.Equal(t, numGoroutines-1, failureCount)
+}